@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/cloudfoundry/gosteno"
 	"github.com/cloudfoundry/loggregatorlib/cfcomponent"
+	"sync"
 	"time"
 )
 
@@ -27,15 +28,23 @@ type Config struct {
 	Zone                          string
 	ContainerMetricTTLSeconds     int
 	SinkInactivityTimeoutSeconds  int
+
+	mu          sync.RWMutex
+	subscribers []chan ConfigUpdate
 }
 
 func (c *Config) Validate(logger *gosteno.Logger) (err error) {
-	if c.MaxRetainedLogMessages == 0 {
+	c.mu.RLock()
+	maxRetainedLogMessages := c.MaxRetainedLogMessages
+	blackListIps := c.BlackListIps
+	c.mu.RUnlock()
+
+	if maxRetainedLogMessages == 0 {
 		return errors.New("Need max number of log messages to retain per application")
 	}
 
-	if c.BlackListIps != nil {
-		err = iprange.ValidateIpAddresses(c.BlackListIps)
+	if blackListIps != nil {
+		err = iprange.ValidateIpAddresses(blackListIps)
 		if err != nil {
 			return err
 		}
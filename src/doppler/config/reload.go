@@ -0,0 +1,168 @@
+package config
+
+import (
+	"doppler/iprange"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cloudfoundry/gosteno"
+)
+
+// ConfigUpdate carries the new value of every field Reload is allowed to
+// change, published to subscribers after a successful reload.
+type ConfigUpdate struct {
+	BlackListIps                 []iprange.IPRange
+	MaxRetainedLogMessages       uint32
+	WSMessageBufferSize          uint
+	ContainerMetricTTLSeconds    int
+	SinkInactivityTimeoutSeconds int
+	SharedSecret                 string
+}
+
+// Reload re-reads the JSON config file at path and atomically swaps in its
+// mutable fields (BlackListIps, MaxRetainedLogMessages,
+// WSMessageBufferSize, ContainerMetricTTLSeconds,
+// SinkInactivityTimeoutSeconds, SharedSecret), then publishes the new
+// values to every channel returned by Subscribe. Fields that require a
+// restart to change safely (ports, EtcdUrls) are rejected with an error
+// if the file on disk tries to change them.
+func (c *Config) Reload(path string, logger *gosteno.Logger) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: reading %s: %s", path, err)
+	}
+
+	var next Config
+	if err := json.Unmarshal(raw, &next); err != nil {
+		return fmt.Errorf("config: parsing %s: %s", path, err)
+	}
+
+	if err := c.validateImmutable(&next); err != nil {
+		return err
+	}
+
+	if err := next.Validate(logger); err != nil {
+		return fmt.Errorf("config: reloaded config is invalid: %s", err)
+	}
+
+	update := ConfigUpdate{
+		BlackListIps:                 next.BlackListIps,
+		MaxRetainedLogMessages:       next.MaxRetainedLogMessages,
+		WSMessageBufferSize:          next.WSMessageBufferSize,
+		ContainerMetricTTLSeconds:    next.ContainerMetricTTLSeconds,
+		SinkInactivityTimeoutSeconds: next.SinkInactivityTimeoutSeconds,
+		SharedSecret:                 next.SharedSecret,
+	}
+
+	c.mu.Lock()
+	c.BlackListIps = update.BlackListIps
+	c.MaxRetainedLogMessages = update.MaxRetainedLogMessages
+	c.WSMessageBufferSize = update.WSMessageBufferSize
+	c.ContainerMetricTTLSeconds = update.ContainerMetricTTLSeconds
+	c.SinkInactivityTimeoutSeconds = update.SinkInactivityTimeoutSeconds
+	c.SharedSecret = update.SharedSecret
+	subscribers := make([]chan ConfigUpdate, len(c.subscribers))
+	copy(subscribers, c.subscribers)
+	c.mu.Unlock()
+
+	logger.Infof("Config: Reloaded %s", path)
+
+	for _, subscriber := range subscribers {
+		// The channel is buffered at 1 and only ever holds the latest
+		// reload: if a subscriber hasn't drained the previous update yet,
+		// discard it and replace it with this one rather than dropping
+		// this update on the floor. That way a subscriber that's merely
+		// slow to consume still eventually sees the most recent config,
+		// even if a SIGHUP arrives again before it catches up.
+		select {
+		case subscriber <- update:
+		default:
+			select {
+			case <-subscriber:
+			default:
+			}
+			select {
+			case subscriber <- update:
+			default:
+			}
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns a channel that receives a ConfigUpdate after every
+// successful Reload, so components that hold their own copy of the
+// mutable settings (rather than a pointer to this Config) can adopt them
+// in place. ReloadWatcher is the reference subscriber: the IP blacklist
+// filter, sink manager, and websocket server should each hold a
+// ReloadWatcher rather than reading Config directly, so all six mutable
+// fields actually change behavior on a SIGHUP instead of only the ones a
+// component happens to read.
+//
+// The channel only ever holds the single most recent update: Reload
+// replaces a pending, unconsumed value rather than dropping the new one,
+// so a subscriber that's behind still catches up to the latest config
+// instead of missing a reload that happened while it wasn't looking.
+func (c *Config) Subscribe() <-chan ConfigUpdate {
+	ch := make(chan ConfigUpdate, 1)
+
+	c.mu.Lock()
+	c.subscribers = append(c.subscribers, ch)
+	c.mu.Unlock()
+
+	return ch
+}
+
+// validateImmutable rejects a reload that tries to change a field that
+// can't be swapped in safely without restarting doppler (listen ports,
+// etcd endpoints).
+func (c *Config) validateImmutable(next *Config) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.DropsondeIncomingMessagesPort != next.DropsondeIncomingMessagesPort {
+		return fmt.Errorf("config: DropsondeIncomingMessagesPort cannot change on reload")
+	}
+	if c.OutgoingPort != next.OutgoingPort {
+		return fmt.Errorf("config: OutgoingPort cannot change on reload")
+	}
+	if !stringSlicesEqual(c.EtcdUrls, next.EtcdUrls) {
+		return fmt.Errorf("config: EtcdUrls cannot change on reload")
+	}
+
+	return nil
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchForReload installs a SIGHUP handler that calls conf.Reload(path,
+// logger) whenever doppler receives the signal, so operators can tune
+// blacklists and retention live during incident response instead of
+// rolling doppler VMs.
+func WatchForReload(conf *Config, path string, logger *gosteno.Logger) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	go func() {
+		for range sigChan {
+			if err := conf.Reload(path, logger); err != nil {
+				logger.Errorf("Config: Failed to reload %s: %s", path, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"doppler/iprange"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/gosteno"
+)
+
+func writeTempConfig(t *testing.T, conf *Config) string {
+	t.Helper()
+
+	raw, err := json.Marshal(conf)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %s", err)
+	}
+
+	file, err := ioutil.TempFile("", "doppler-config")
+	if err != nil {
+		t.Fatalf("failed to create temp config file: %s", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(raw); err != nil {
+		t.Fatalf("failed to write temp config file: %s", err)
+	}
+
+	return file.Name()
+}
+
+func TestValidateImmutableAcceptsUnchangedFields(t *testing.T) {
+	current := &Config{
+		DropsondeIncomingMessagesPort: 3457,
+		OutgoingPort:                  8081,
+		EtcdUrls:                      []string{"http://etcd.example.com:4001"},
+	}
+	next := &Config{
+		DropsondeIncomingMessagesPort: 3457,
+		OutgoingPort:                  8081,
+		EtcdUrls:                      []string{"http://etcd.example.com:4001"},
+	}
+
+	if err := current.validateImmutable(next); err != nil {
+		t.Errorf("expected no error for unchanged immutable fields, got %s", err)
+	}
+}
+
+func TestValidateImmutableRejectsPortChanges(t *testing.T) {
+	current := &Config{DropsondeIncomingMessagesPort: 3457, OutgoingPort: 8081}
+
+	next := &Config{DropsondeIncomingMessagesPort: 3458, OutgoingPort: 8081}
+	if err := current.validateImmutable(next); err == nil {
+		t.Error("expected an error when DropsondeIncomingMessagesPort changes")
+	}
+
+	next = &Config{DropsondeIncomingMessagesPort: 3457, OutgoingPort: 8082}
+	if err := current.validateImmutable(next); err == nil {
+		t.Error("expected an error when OutgoingPort changes")
+	}
+}
+
+func TestValidateImmutableRejectsEtcdUrlChanges(t *testing.T) {
+	current := &Config{EtcdUrls: []string{"http://etcd.example.com:4001"}}
+
+	next := &Config{EtcdUrls: []string{"http://etcd.example.com:4002"}}
+	if err := current.validateImmutable(next); err == nil {
+		t.Error("expected an error when EtcdUrls changes")
+	}
+
+	next = &Config{EtcdUrls: []string{"http://etcd.example.com:4001", "http://etcd2.example.com:4001"}}
+	if err := current.validateImmutable(next); err == nil {
+		t.Error("expected an error when EtcdUrls grows")
+	}
+}
+
+func TestReloadWatcherAdoptsReloadedSettings(t *testing.T) {
+	initial := []iprange.IPRange{{Start: "10.0.0.1", End: "10.0.0.2"}}
+	conf := &Config{BlackListIps: initial, MaxRetainedLogMessages: 100, SharedSecret: "old-secret"}
+	watcher := NewReloadWatcher(conf)
+
+	if got := watcher.BlackListIps(); len(got) != 1 {
+		t.Fatalf("expected initial blacklist to have 1 entry, got %d", len(got))
+	}
+	if got := watcher.MaxRetainedLogMessages(); got != 100 {
+		t.Fatalf("expected initial MaxRetainedLogMessages to be 100, got %d", got)
+	}
+
+	updated := []iprange.IPRange{{Start: "192.168.0.1", End: "192.168.0.2"}}
+	conf.mu.Lock()
+	subscriber := conf.subscribers[0]
+	conf.mu.Unlock()
+	subscriber <- ConfigUpdate{BlackListIps: updated, MaxRetainedLogMessages: 200, SharedSecret: "new-secret"}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		gotIps := watcher.BlackListIps()
+		if len(gotIps) == 1 && gotIps[0].Start == "192.168.0.1" &&
+			watcher.MaxRetainedLogMessages() == 200 &&
+			watcher.SharedSecret() == "new-secret" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("expected watcher to adopt the reloaded settings")
+}
+
+func TestReloadAppliesMutableFieldsAndNotifiesSubscribers(t *testing.T) {
+	conf := &Config{
+		DropsondeIncomingMessagesPort: 3457,
+		OutgoingPort:                  8081,
+		EtcdUrls:                      []string{"http://etcd.example.com:4001"},
+		MaxRetainedLogMessages:        100,
+		SharedSecret:                  "old-secret",
+	}
+	subscriber := conf.Subscribe()
+
+	next := &Config{
+		DropsondeIncomingMessagesPort: 3457,
+		OutgoingPort:                  8081,
+		EtcdUrls:                      []string{"http://etcd.example.com:4001"},
+		MaxRetainedLogMessages:        200,
+		SharedSecret:                  "new-secret",
+	}
+	path := writeTempConfig(t, next)
+	defer os.Remove(path)
+
+	if err := conf.Reload(path, &gosteno.Logger{}); err != nil {
+		t.Fatalf("expected reload to succeed, got %s", err)
+	}
+
+	if conf.MaxRetainedLogMessages != 200 {
+		t.Errorf("expected MaxRetainedLogMessages to reload to 200, got %d", conf.MaxRetainedLogMessages)
+	}
+	if conf.SharedSecret != "new-secret" {
+		t.Errorf("expected SharedSecret to reload to new-secret, got %s", conf.SharedSecret)
+	}
+
+	select {
+	case update := <-subscriber:
+		if update.MaxRetainedLogMessages != 200 || update.SharedSecret != "new-secret" {
+			t.Errorf("expected subscriber to receive the reloaded values, got %+v", update)
+		}
+	default:
+		t.Error("expected a subscriber to receive a ConfigUpdate after Reload")
+	}
+}
+
+func TestReloadRejectsImmutableFieldChange(t *testing.T) {
+	conf := &Config{
+		DropsondeIncomingMessagesPort: 3457,
+		OutgoingPort:                  8081,
+		MaxRetainedLogMessages:        100,
+	}
+
+	next := &Config{
+		DropsondeIncomingMessagesPort: 3458,
+		OutgoingPort:                  8081,
+		MaxRetainedLogMessages:        100,
+	}
+	path := writeTempConfig(t, next)
+	defer os.Remove(path)
+
+	if err := conf.Reload(path, &gosteno.Logger{}); err == nil {
+		t.Error("expected reload to reject a changed DropsondeIncomingMessagesPort")
+	}
+	if conf.MaxRetainedLogMessages != 100 {
+		t.Error("expected a rejected reload to leave the existing config untouched")
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	conf := &Config{MaxRetainedLogMessages: 100}
+
+	next := &Config{MaxRetainedLogMessages: 0}
+	path := writeTempConfig(t, next)
+	defer os.Remove(path)
+
+	if err := conf.Reload(path, &gosteno.Logger{}); err == nil {
+		t.Error("expected reload to surface Validate's error for an invalid reloaded config")
+	}
+}
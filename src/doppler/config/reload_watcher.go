@@ -0,0 +1,78 @@
+package config
+
+import (
+	"doppler/iprange"
+	"sync/atomic"
+)
+
+// ReloadWatcher is a live, lock-free view of every field Reload can change:
+// the IP blacklist filter, sink manager, and websocket server each hold
+// their own copy of these settings rather than a pointer to Config, so
+// they need something that actually applies a SIGHUP reload rather than a
+// Config they never look at again after startup. ReloadWatcher subscribes
+// once and keeps the latest ConfigUpdate in an atomic.Value, so reading it
+// on a hot path (e.g. per-connection blacklist checks) never takes
+// Config's mutex.
+type ReloadWatcher struct {
+	current atomic.Value // ConfigUpdate
+}
+
+// NewReloadWatcher snapshots conf's current mutable settings and applies
+// every subsequent Reload to that snapshot for the lifetime of conf.
+func NewReloadWatcher(conf *Config) *ReloadWatcher {
+	w := &ReloadWatcher{}
+
+	conf.mu.RLock()
+	w.current.Store(ConfigUpdate{
+		BlackListIps:                 conf.BlackListIps,
+		MaxRetainedLogMessages:       conf.MaxRetainedLogMessages,
+		WSMessageBufferSize:          conf.WSMessageBufferSize,
+		ContainerMetricTTLSeconds:    conf.ContainerMetricTTLSeconds,
+		SinkInactivityTimeoutSeconds: conf.SinkInactivityTimeoutSeconds,
+		SharedSecret:                 conf.SharedSecret,
+	})
+	conf.mu.RUnlock()
+
+	updates := conf.Subscribe()
+	go func() {
+		for update := range updates {
+			w.current.Store(update)
+		}
+	}()
+
+	return w
+}
+
+// BlackListIps returns the most recently reloaded blacklist.
+func (w *ReloadWatcher) BlackListIps() []iprange.IPRange {
+	return w.current.Load().(ConfigUpdate).BlackListIps
+}
+
+// MaxRetainedLogMessages returns the most recently reloaded per-app
+// retention count.
+func (w *ReloadWatcher) MaxRetainedLogMessages() uint32 {
+	return w.current.Load().(ConfigUpdate).MaxRetainedLogMessages
+}
+
+// WSMessageBufferSize returns the most recently reloaded websocket
+// message buffer size.
+func (w *ReloadWatcher) WSMessageBufferSize() uint {
+	return w.current.Load().(ConfigUpdate).WSMessageBufferSize
+}
+
+// ContainerMetricTTLSeconds returns the most recently reloaded container
+// metric TTL.
+func (w *ReloadWatcher) ContainerMetricTTLSeconds() int {
+	return w.current.Load().(ConfigUpdate).ContainerMetricTTLSeconds
+}
+
+// SinkInactivityTimeoutSeconds returns the most recently reloaded sink
+// inactivity timeout.
+func (w *ReloadWatcher) SinkInactivityTimeoutSeconds() int {
+	return w.current.Load().(ConfigUpdate).SinkInactivityTimeoutSeconds
+}
+
+// SharedSecret returns the most recently reloaded shared secret.
+func (w *ReloadWatcher) SharedSecret() string {
+	return w.current.Load().(ConfigUpdate).SharedSecret
+}
@@ -0,0 +1,64 @@
+package buffer
+
+import (
+	"sync/atomic"
+
+	"github.com/cloudfoundry/dropsonde/events"
+	"github.com/cloudfoundry/gosteno"
+)
+
+// TruncatingBuffer sits between a sink's raw input and its (possibly slow)
+// drain. When the drain falls behind, the oldest buffered envelope is
+// dropped in favor of the newest rather than blocking the firehose.
+type TruncatingBuffer struct {
+	inputChannel    <-chan *events.Envelope
+	outputChannel   chan *events.Envelope
+	droppedCount    int64
+	logger          *gosteno.Logger
+	dropsondeOrigin string
+}
+
+// RunTruncatingBuffer starts draining inputChannel into a fixed-size
+// buffer of bufferSize envelopes and returns it; read the envelopes back
+// out via GetOutputChannel.
+func RunTruncatingBuffer(inputChannel <-chan *events.Envelope, bufferSize int, logger *gosteno.Logger, dropsondeOrigin string) *TruncatingBuffer {
+	b := &TruncatingBuffer{
+		inputChannel:    inputChannel,
+		outputChannel:   make(chan *events.Envelope, bufferSize),
+		logger:          logger,
+		dropsondeOrigin: dropsondeOrigin,
+	}
+	go b.run()
+	return b
+}
+
+func (b *TruncatingBuffer) run() {
+	defer close(b.outputChannel)
+
+	for v := range b.inputChannel {
+		select {
+		case b.outputChannel <- v:
+		default:
+			// The reader may have emptied the buffer since the send above
+			// failed, so only evict if there is still something to evict.
+			select {
+			case <-b.outputChannel:
+				atomic.AddInt64(&b.droppedCount, 1)
+				b.logger.Debugf("TruncatingBuffer: Dropped message from origin %s, buffer is full", b.dropsondeOrigin)
+			default:
+			}
+			b.outputChannel <- v
+		}
+	}
+}
+
+func (b *TruncatingBuffer) GetOutputChannel() <-chan *events.Envelope {
+	return b.outputChannel
+}
+
+// GetDroppedMessageCount returns the number of envelopes dropped since the
+// last call, resetting the counter. Callers are expected to poll it once
+// per envelope consumed from GetOutputChannel and accumulate the result.
+func (b *TruncatingBuffer) GetDroppedMessageCount() int64 {
+	return atomic.SwapInt64(&b.droppedCount, 0)
+}
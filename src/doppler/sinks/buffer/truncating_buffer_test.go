@@ -0,0 +1,108 @@
+package buffer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/dropsonde/events"
+	"github.com/cloudfoundry/gosteno"
+)
+
+func envelopeWithOrigin(origin string) *events.Envelope {
+	return &events.Envelope{Origin: &origin}
+}
+
+func TestTruncatingBufferDropsOldestWhenFull(t *testing.T) {
+	input := make(chan *events.Envelope, 3)
+	input <- envelopeWithOrigin("first")
+	input <- envelopeWithOrigin("second")
+	input <- envelopeWithOrigin("third")
+	close(input)
+
+	buf := RunTruncatingBuffer(input, 2, &gosteno.Logger{}, "origin")
+
+	// Let the third envelope arrive while the buffer is full before reading
+	// anything, so no envelope is handed straight to a waiting reader.
+	fillDeadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&buf.droppedCount) == 0 && time.Now().Before(fillDeadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	var kept []*events.Envelope
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		envelope, ok := <-buf.GetOutputChannel()
+		if !ok {
+			break
+		}
+		kept = append(kept, envelope)
+	}
+
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 surviving envelopes, got %d", len(kept))
+	}
+	if kept[0].GetOrigin() != "second" || kept[1].GetOrigin() != "third" {
+		t.Errorf("expected the oldest envelope to be dropped, got %s then %s", kept[0].GetOrigin(), kept[1].GetOrigin())
+	}
+	if dropped := buf.GetDroppedMessageCount(); dropped != 1 {
+		t.Errorf("expected 1 dropped message, got %d", dropped)
+	}
+}
+
+func TestTruncatingBufferKeepsEverythingWithinCapacity(t *testing.T) {
+	input := make(chan *events.Envelope, 2)
+	input <- envelopeWithOrigin("first")
+	input <- envelopeWithOrigin("second")
+	close(input)
+
+	buf := RunTruncatingBuffer(input, 2, &gosteno.Logger{}, "origin")
+
+	var kept []*events.Envelope
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		envelope, ok := <-buf.GetOutputChannel()
+		if !ok {
+			break
+		}
+		kept = append(kept, envelope)
+	}
+
+	if len(kept) != 2 {
+		t.Fatalf("expected both envelopes to survive, got %d", len(kept))
+	}
+	if dropped := buf.GetDroppedMessageCount(); dropped != 0 {
+		t.Errorf("expected no drops within capacity, got %d", dropped)
+	}
+}
+
+func TestTruncatingBufferGetDroppedMessageCountResetsCounter(t *testing.T) {
+	input := make(chan *events.Envelope, 3)
+	input <- envelopeWithOrigin("first")
+	input <- envelopeWithOrigin("second")
+	input <- envelopeWithOrigin("third")
+	close(input)
+
+	buf := RunTruncatingBuffer(input, 1, &gosteno.Logger{}, "origin")
+
+	// Let the buffer overflow before reading, so the drops don't depend on
+	// how fast this reader keeps up.
+	fillDeadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&buf.droppedCount) == 0 && time.Now().Before(fillDeadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := <-buf.GetOutputChannel(); !ok {
+			break
+		}
+	}
+
+	if dropped := buf.GetDroppedMessageCount(); dropped == 0 {
+		t.Error("expected the first call to report the accumulated drops")
+	}
+	if dropped := buf.GetDroppedMessageCount(); dropped != 0 {
+		t.Errorf("expected the counter to reset after being read, got %d", dropped)
+	}
+}
@@ -0,0 +1,41 @@
+package sinks
+
+import (
+	"fmt"
+	"net/url"
+
+	"doppler/sinks/https"
+	"doppler/sinks/kafka"
+	"doppler/sinks/syslog"
+	"doppler/sinks/syslogwriter"
+
+	"github.com/cloudfoundry/gosteno"
+)
+
+// NewDrainSink builds the DrainSink for drainUrlRaw, dispatching on its
+// scheme: "syslog"/"syslog-tls"/"syslog-json" sinks forward through
+// syslogwriter, "https" sinks batch-POST gzipped JSON, and "kafka" sinks
+// publish to a topic. Callers that already know they want a particular
+// backend (e.g. tests) can still construct it directly via that package's
+// constructor.
+func NewDrainSink(appId, drainUrlRaw string, givenLogger *gosteno.Logger, errorHandler func(string, string, string), dropsondeOrigin string, skipCertVerify bool) (DrainSink, error) {
+	drainUrl, err := url.Parse(drainUrlRaw)
+	if err != nil {
+		return nil, fmt.Errorf("sinks: invalid drain URL %q: %s", drainUrlRaw, err)
+	}
+
+	switch drainUrl.Scheme {
+	case "syslog", "syslog-tls", "syslog-json":
+		writer, err := syslogwriter.NewWriter(appId, dropsondeOrigin, drainUrl, skipCertVerify)
+		if err != nil {
+			return nil, err
+		}
+		return syslog.NewSyslogSink(appId, drainUrlRaw, givenLogger, writer, errorHandler, dropsondeOrigin), nil
+	case "https":
+		return https.NewHttpsSink(appId, drainUrl, givenLogger, errorHandler, dropsondeOrigin), nil
+	case "kafka":
+		return kafka.NewKafkaSink(appId, drainUrl, givenLogger, errorHandler, dropsondeOrigin), nil
+	default:
+		return nil, fmt.Errorf("sinks: unsupported drain scheme %q", drainUrl.Scheme)
+	}
+}
@@ -0,0 +1,68 @@
+package sinks
+
+import (
+	"testing"
+
+	"doppler/sinks/https"
+	"doppler/sinks/kafka"
+	"doppler/sinks/syslog"
+
+	"github.com/cloudfoundry/gosteno"
+)
+
+func noopErrorHandler(errorMessage, appId, drainUrl string) {}
+
+func TestNewDrainSinkDispatchesByScheme(t *testing.T) {
+	logger := &gosteno.Logger{}
+
+	tests := []struct {
+		scheme   string
+		drainUrl string
+	}{
+		{"syslog", "syslog://example.com:514"},
+		{"syslog-tls", "syslog-tls://example.com:6514"},
+		{"syslog-json", "syslog-json://example.com:6514"},
+		{"https", "https://example.com/drain"},
+		{"kafka", "kafka://example.com:9092/topic"},
+	}
+
+	for _, test := range tests {
+		sink, err := NewDrainSink("appId", test.drainUrl, logger, noopErrorHandler, "origin", false)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.scheme, err)
+		}
+
+		switch test.scheme {
+		case "syslog", "syslog-tls", "syslog-json":
+			if _, ok := sink.(*syslog.SyslogSink); !ok {
+				t.Errorf("%s: expected *syslog.SyslogSink, got %T", test.scheme, sink)
+			}
+		case "https":
+			if _, ok := sink.(*https.HttpsSink); !ok {
+				t.Errorf("%s: expected *https.HttpsSink, got %T", test.scheme, sink)
+			}
+		case "kafka":
+			if _, ok := sink.(*kafka.KafkaSink); !ok {
+				t.Errorf("%s: expected *kafka.KafkaSink, got %T", test.scheme, sink)
+			}
+		}
+	}
+}
+
+func TestNewDrainSinkRejectsUnsupportedScheme(t *testing.T) {
+	logger := &gosteno.Logger{}
+
+	_, err := NewDrainSink("appId", "carrier-pigeon://example.com", logger, noopErrorHandler, "origin", false)
+	if err == nil {
+		t.Fatal("expected an error for an unsupported drain scheme, got none")
+	}
+}
+
+func TestNewDrainSinkRejectsMalformedUrl(t *testing.T) {
+	logger := &gosteno.Logger{}
+
+	_, err := NewDrainSink("appId", "://bad-url", logger, noopErrorHandler, "origin", false)
+	if err == nil {
+		t.Fatal("expected an error for a malformed drain URL, got none")
+	}
+}
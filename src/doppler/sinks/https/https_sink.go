@@ -0,0 +1,262 @@
+package https
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"doppler/sinks/buffer"
+	"doppler/sinks/retrystrategy"
+
+	"github.com/cloudfoundry/dropsonde/events"
+	"github.com/cloudfoundry/gosteno"
+	"github.com/cloudfoundry/loggregatorlib/cfcomponent/instrumentation"
+)
+
+const (
+	batchSize = 100
+	// maxRetainedBatchSize bounds how far the retained batch can grow while
+	// retrying a failed flush during a drain outage. Past this, the oldest
+	// envelopes are dropped (and counted) rather than pinning an
+	// ever-growing batch in memory, which would defeat the truncating
+	// buffer's own memory bound.
+	maxRetainedBatchSize = batchSize * 5
+	flushInterval        = time.Second
+)
+
+// jsonEnvelope is the subset of an envelope's LogMessage that gets shipped
+// to an HTTPS drain; it mirrors the fields a text-oriented log shipper
+// cares about rather than the full protobuf envelope.
+type jsonEnvelope struct {
+	Origin     string `json:"origin"`
+	AppId      string `json:"app_id"`
+	SourceType string `json:"source_type"`
+	SourceId   string `json:"source_instance"`
+	Message    string `json:"message"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// HttpsSink batches envelopes and POSTs them as gzip-compressed JSON to a
+// drain URL, authenticating with the URL's userinfo when present. It reuses
+// the retry/backoff, truncating buffer, and drop instrumentation that
+// syslog.SyslogSink established, but owns its own connect/write/close.
+type HttpsSink struct {
+	*gosteno.Logger
+	appId               string
+	drainUrl            *url.URL
+	client              *http.Client
+	droppedMessageCount int64
+	handleSendError     func(errorMessage, appId, drainUrl string)
+	disconnectChannel   chan struct{}
+	disconnectOnce      sync.Once
+	dropsondeOrigin     string
+}
+
+func NewHttpsSink(appId string, drainUrl *url.URL, givenLogger *gosteno.Logger, errorHandler func(string, string, string), dropsondeOrigin string) *HttpsSink {
+	return &HttpsSink{
+		appId:             appId,
+		drainUrl:          drainUrl,
+		Logger:            givenLogger,
+		client:            &http.Client{Timeout: 5 * time.Second},
+		handleSendError:   errorHandler,
+		disconnectChannel: make(chan struct{}),
+		dropsondeOrigin:   dropsondeOrigin,
+	}
+}
+
+// redactedDrainUrl returns the drain URL with any Basic-Auth userinfo
+// stripped, for use anywhere the URL is logged, reported as an
+// instrumentation tag, or otherwise surfaced outside the actual HTTP
+// request — url.URL.String() includes the cleartext password otherwise.
+func (s *HttpsSink) redactedDrainUrl() string {
+	redacted := *s.drainUrl
+	redacted.User = nil
+	return redacted.String()
+}
+
+func (s *HttpsSink) Run(inputChan <-chan *events.Envelope) {
+	s.Infof("Https Sink %s: Running.", s.redactedDrainUrl())
+	defer s.Errorf("Https Sink %s: Stopped.", s.redactedDrainUrl())
+
+	backoffStrategy := retrystrategy.NewExponentialRetryStrategy()
+	numberOfTries := 0
+	filteredChan := make(chan *events.Envelope)
+
+	go func() {
+		defer close(filteredChan)
+
+		for {
+			select {
+			case v, ok := <-inputChan:
+				if !ok {
+					return
+				}
+
+				if v.GetEventType() != events.Envelope_LogMessage {
+					continue
+				}
+
+				filteredChan <- v
+			case <-s.disconnectChannel:
+				return
+			}
+		}
+	}()
+
+	buf := buffer.RunTruncatingBuffer(filteredChan, batchSize, s.Logger, s.dropsondeOrigin)
+	defer s.client.CloseIdleConnections()
+
+	batch := make([]*events.Envelope, 0, batchSize)
+	flushTimer := time.NewTimer(flushInterval)
+	defer flushTimer.Stop()
+
+	for {
+		select {
+		case <-s.disconnectChannel:
+			s.flush(batch)
+			return
+		case messageEnvelope, ok := <-buf.GetOutputChannel():
+			atomic.AddInt64(&s.droppedMessageCount, buf.GetDroppedMessageCount())
+			if !ok {
+				s.flush(batch)
+				return
+			}
+
+			batch = append(batch, messageEnvelope)
+			var dropped int
+			batch, dropped = capBatch(batch, maxRetainedBatchSize)
+			if dropped > 0 {
+				atomic.AddInt64(&s.droppedMessageCount, int64(dropped))
+			}
+			if len(batch) < batchSize {
+				continue
+			}
+
+			if s.flush(batch) {
+				numberOfTries = 0
+				batch = batch[:0]
+			} else {
+				numberOfTries++
+				backoffTimer := time.NewTimer(backoffStrategy(numberOfTries))
+				select {
+				case <-backoffTimer.C:
+				case <-s.disconnectChannel:
+					backoffTimer.Stop()
+					return
+				}
+			}
+		case <-flushTimer.C:
+			if len(batch) > 0 && s.flush(batch) {
+				batch = batch[:0]
+			}
+			flushTimer.Reset(flushInterval)
+		}
+	}
+}
+
+// capBatch trims batch down to maxSize by dropping the oldest entries,
+// reporting how many were dropped so the caller can update its
+// instrumentation. It's a no-op (0 dropped) while batch is within bounds.
+func capBatch(batch []*events.Envelope, maxSize int) ([]*events.Envelope, int) {
+	overflow := len(batch) - maxSize
+	if overflow <= 0 {
+		return batch, 0
+	}
+	return append(batch[:0], batch[overflow:]...), overflow
+}
+
+func (s *HttpsSink) flush(batch []*events.Envelope) bool {
+	if len(batch) == 0 {
+		return true
+	}
+
+	payload := make([]jsonEnvelope, 0, len(batch))
+	for _, e := range batch {
+		logMessage := e.GetLogMessage()
+		payload = append(payload, jsonEnvelope{
+			Origin:     e.GetOrigin(),
+			AppId:      s.appId,
+			SourceType: logMessage.GetSourceType(),
+			SourceId:   logMessage.GetSourceInstance(),
+			Message:    string(logMessage.GetMessage()),
+			Timestamp:  logMessage.GetTimestamp(),
+		})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		s.handleSendError(fmt.Sprintf("Https Sink %s: Error marshalling batch: %v", s.redactedDrainUrl(), err), s.appId, s.redactedDrainUrl())
+		return false
+	}
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	gzipWriter.Write(body)
+	gzipWriter.Close()
+
+	req, err := http.NewRequest("POST", s.drainUrl.String(), &gzipped)
+	if err != nil {
+		s.handleSendError(fmt.Sprintf("Https Sink %s: Error building request: %v", s.redactedDrainUrl(), err), s.appId, s.redactedDrainUrl())
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	if user := s.drainUrl.User; user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.handleSendError(fmt.Sprintf("Https Sink %s: Error when trying to send data to sink. Err: %v", s.redactedDrainUrl(), err), s.appId, s.redactedDrainUrl())
+		return false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		s.handleSendError(fmt.Sprintf("Https Sink %s: Drain responded with status %d", s.redactedDrainUrl(), resp.StatusCode), s.appId, s.redactedDrainUrl())
+		return false
+	}
+
+	s.Debugf("Https Sink %s: Successfully sent batch of %d messages", s.redactedDrainUrl(), len(batch))
+	return true
+}
+
+func (s *HttpsSink) Disconnect() {
+	s.disconnectOnce.Do(func() { close(s.disconnectChannel) })
+}
+
+func (s *HttpsSink) Identifier() string {
+	return s.redactedDrainUrl()
+}
+
+func (s *HttpsSink) StreamId() string {
+	return s.appId
+}
+
+func (s *HttpsSink) ShouldReceiveErrors() bool {
+	return false
+}
+
+func (s *HttpsSink) GetInstrumentationMetric() instrumentation.Metric {
+	count := atomic.LoadInt64(&s.droppedMessageCount)
+	return instrumentation.Metric{Name: "numberOfMessagesLost", Tags: map[string]interface{}{"appId": s.appId, "drainUrl": s.redactedDrainUrl()}, Value: count}
+}
+
+func (s *HttpsSink) GetInstrumentationMetrics() []instrumentation.Metric {
+	count := atomic.LoadInt64(&s.droppedMessageCount)
+	if count == 0 {
+		return nil
+	}
+	return []instrumentation.Metric{s.GetInstrumentationMetric()}
+}
+
+func (s *HttpsSink) UpdateDroppedMessageCount(messageCount int64) {
+	atomic.AddInt64(&s.droppedMessageCount, messageCount)
+}
@@ -0,0 +1,83 @@
+package https
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cloudfoundry/dropsonde/events"
+	"github.com/cloudfoundry/gosteno"
+)
+
+func newTestBatch(n int) []*events.Envelope {
+	batch := make([]*events.Envelope, 0, n)
+	for i := 0; i < n; i++ {
+		origin := "origin"
+		batch = append(batch, &events.Envelope{Origin: &origin})
+	}
+	return batch
+}
+
+func TestCapBatchIsNoopWithinBounds(t *testing.T) {
+	batch := newTestBatch(5)
+
+	kept, dropped := capBatch(batch, 10)
+
+	if dropped != 0 {
+		t.Errorf("expected no drops within bounds, got %d", dropped)
+	}
+	if len(kept) != 5 {
+		t.Errorf("expected batch to be unchanged, got %d entries", len(kept))
+	}
+}
+
+func TestCapBatchDropsOldestPastMaxSize(t *testing.T) {
+	batch := newTestBatch(12)
+	last := batch[len(batch)-1]
+
+	kept, dropped := capBatch(batch, 10)
+
+	if dropped != 2 {
+		t.Fatalf("expected 2 dropped entries, got %d", dropped)
+	}
+	if len(kept) != 10 {
+		t.Fatalf("expected 10 retained entries, got %d", len(kept))
+	}
+	if kept[len(kept)-1] != last {
+		t.Error("expected the newest entry to survive the cap")
+	}
+}
+
+func TestFlushRetriesTheSameBatchAfterAFailure(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	drainUrl, _ := url.Parse(server.URL)
+	var sendErrors int
+	sink := NewHttpsSink("app-id", drainUrl, &gosteno.Logger{}, func(string, string, string) { sendErrors++ }, "origin")
+
+	batch := newTestBatch(1)
+
+	if sink.flush(batch) {
+		t.Fatal("expected the first flush to fail")
+	}
+	if sendErrors != 1 {
+		t.Fatalf("expected the failed flush to report a send error, got %d", sendErrors)
+	}
+
+	if !sink.flush(batch) {
+		t.Fatal("expected retrying the same batch to succeed")
+	}
+	if attempts != 2 {
+		t.Fatalf("expected exactly 2 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,189 @@
+package kafka
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"doppler/sinks/buffer"
+	"doppler/sinks/retrystrategy"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudfoundry/dropsonde/events"
+	"github.com/cloudfoundry/gosteno"
+	"github.com/cloudfoundry/loggregatorlib/cfcomponent/instrumentation"
+)
+
+// KafkaSink publishes log messages to a Kafka topic, keyed by appId so a
+// single consumer partition sees an application's messages in order. It
+// reuses the retry/backoff, truncating buffer, and drop instrumentation
+// that syslog.SyslogSink established, but owns its own connect/write/close.
+type KafkaSink struct {
+	*gosteno.Logger
+	appId               string
+	drainUrl            *url.URL
+	topic               string
+	brokers             []string
+	producer            sarama.SyncProducer
+	newProducer         func([]string, *sarama.Config) (sarama.SyncProducer, error)
+	droppedMessageCount int64
+	handleSendError     func(errorMessage, appId, drainUrl string)
+	disconnectChannel   chan struct{}
+	disconnectOnce      sync.Once
+	dropsondeOrigin     string
+}
+
+func NewKafkaSink(appId string, drainUrl *url.URL, givenLogger *gosteno.Logger, errorHandler func(string, string, string), dropsondeOrigin string) *KafkaSink {
+	return &KafkaSink{
+		appId:             appId,
+		drainUrl:          drainUrl,
+		topic:             strings.TrimPrefix(drainUrl.Path, "/"),
+		brokers:           []string{drainUrl.Host},
+		Logger:            givenLogger,
+		newProducer:       sarama.NewSyncProducer,
+		handleSendError:   errorHandler,
+		disconnectChannel: make(chan struct{}),
+		dropsondeOrigin:   dropsondeOrigin,
+	}
+}
+
+func (s *KafkaSink) connect() error {
+	s.closeProducer()
+
+	cfg := sarama.NewConfig()
+	cfg.Producer.Return.Successes = true
+	producer, err := s.newProducer(s.brokers, cfg)
+	if err != nil {
+		return err
+	}
+	s.producer = producer
+	return nil
+}
+
+func (s *KafkaSink) Run(inputChan <-chan *events.Envelope) {
+	s.Infof("Kafka Sink %s: Running.", s.drainUrl)
+	defer s.Errorf("Kafka Sink %s: Stopped.", s.drainUrl)
+
+	backoffStrategy := retrystrategy.NewExponentialRetryStrategy()
+	numberOfTries := 0
+	filteredChan := make(chan *events.Envelope)
+
+	go func() {
+		defer close(filteredChan)
+
+		for {
+			select {
+			case v, ok := <-inputChan:
+				if !ok {
+					return
+				}
+
+				if v.GetEventType() != events.Envelope_LogMessage {
+					continue
+				}
+
+				filteredChan <- v
+			case <-s.disconnectChannel:
+				return
+			}
+		}
+	}()
+
+	buf := buffer.RunTruncatingBuffer(filteredChan, 100, s.Logger, s.dropsondeOrigin)
+	timer := time.NewTimer(backoffStrategy(numberOfTries))
+	connected := false
+	defer timer.Stop()
+	defer s.closeProducer()
+
+	for {
+		timer.Reset(backoffStrategy(numberOfTries))
+		select {
+		case <-s.disconnectChannel:
+			return
+		case <-timer.C:
+		}
+
+		if !connected {
+			if err := s.connect(); err != nil {
+				numberOfTries++
+				s.handleSendError(fmt.Sprintf("Kafka Sink %s: Error when dialing out. Backing off for %v. Err: %v", s.drainUrl, backoffStrategy(numberOfTries), err), s.appId, s.drainUrl.String())
+				continue
+			}
+			connected = true
+		}
+
+		select {
+		case <-s.disconnectChannel:
+			return
+		case messageEnvelope, ok := <-buf.GetOutputChannel():
+			atomic.AddInt64(&s.droppedMessageCount, buf.GetDroppedMessageCount())
+			if !ok {
+				return
+			}
+
+			connected = s.sendMessage(messageEnvelope)
+			if connected {
+				numberOfTries = 0
+			} else {
+				numberOfTries++
+			}
+		}
+	}
+}
+
+func (s *KafkaSink) sendMessage(messageEnvelope *events.Envelope) bool {
+	logMessage := messageEnvelope.GetLogMessage()
+
+	_, _, err := s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: s.topic,
+		Key:   sarama.StringEncoder(s.appId),
+		Value: sarama.ByteEncoder(logMessage.GetMessage()),
+	})
+	if err != nil {
+		s.handleSendError(fmt.Sprintf("Kafka Sink %s: Error when trying to send data to sink. Err: %v", s.drainUrl, err), s.appId, s.drainUrl.String())
+		return false
+	}
+	return true
+}
+
+func (s *KafkaSink) closeProducer() {
+	if s.producer != nil {
+		s.producer.Close()
+	}
+}
+
+func (s *KafkaSink) Disconnect() {
+	s.disconnectOnce.Do(func() { close(s.disconnectChannel) })
+}
+
+func (s *KafkaSink) Identifier() string {
+	return s.drainUrl.String()
+}
+
+func (s *KafkaSink) StreamId() string {
+	return s.appId
+}
+
+func (s *KafkaSink) ShouldReceiveErrors() bool {
+	return false
+}
+
+func (s *KafkaSink) GetInstrumentationMetric() instrumentation.Metric {
+	count := atomic.LoadInt64(&s.droppedMessageCount)
+	return instrumentation.Metric{Name: "numberOfMessagesLost", Tags: map[string]interface{}{"appId": s.appId, "drainUrl": s.drainUrl.String()}, Value: count}
+}
+
+func (s *KafkaSink) GetInstrumentationMetrics() []instrumentation.Metric {
+	count := atomic.LoadInt64(&s.droppedMessageCount)
+	if count == 0 {
+		return nil
+	}
+	return []instrumentation.Metric{s.GetInstrumentationMetric()}
+}
+
+func (s *KafkaSink) UpdateDroppedMessageCount(messageCount int64) {
+	atomic.AddInt64(&s.droppedMessageCount, messageCount)
+}
@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+type fakeProducer struct {
+	closed bool
+}
+
+func (p *fakeProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	return 0, 0, nil
+}
+
+func (p *fakeProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	p.closed = true
+	return nil
+}
+
+func newTestSink() *KafkaSink {
+	drainUrl, _ := url.Parse("kafka://broker:9092/topic")
+	return &KafkaSink{
+		drainUrl:          drainUrl,
+		brokers:           []string{drainUrl.Host},
+		disconnectChannel: make(chan struct{}),
+	}
+}
+
+func TestConnectClosesThePreviousProducerBeforeReconnecting(t *testing.T) {
+	sink := newTestSink()
+	stale := &fakeProducer{}
+	sink.producer = stale
+
+	sink.newProducer = func(brokers []string, cfg *sarama.Config) (sarama.SyncProducer, error) {
+		return &fakeProducer{}, nil
+	}
+
+	if err := sink.connect(); err != nil {
+		t.Fatalf("expected connect to succeed, got %s", err)
+	}
+
+	if !stale.closed {
+		t.Error("expected the stale producer to be closed before reconnecting")
+	}
+	if sink.producer == stale {
+		t.Error("expected connect to replace the producer, not reuse the stale one")
+	}
+}
+
+func TestConnectEnablesReturnSuccesses(t *testing.T) {
+	sink := newTestSink()
+
+	var gotConfig *sarama.Config
+	sink.newProducer = func(brokers []string, cfg *sarama.Config) (sarama.SyncProducer, error) {
+		gotConfig = cfg
+		return &fakeProducer{}, nil
+	}
+
+	if err := sink.connect(); err != nil {
+		t.Fatalf("expected connect to succeed, got %s", err)
+	}
+
+	if !gotConfig.Producer.Return.Successes {
+		t.Error("expected connect to require Producer.Return.Successes so NewSyncProducer accepts the config")
+	}
+}
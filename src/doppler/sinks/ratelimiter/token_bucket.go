@@ -0,0 +1,85 @@
+package ratelimiter
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a token-bucket rate limiter: tokens refill continuously at
+// a configurable rate up to a burst ceiling, and Take blocks the caller
+// until a token is available. The rate can be adjusted in place, which
+// callers use to implement AIMD-style backpressure.
+type TokenBucket struct {
+	mu         sync.Mutex
+	ratePerSec float64
+	burst      float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewTokenBucket returns a TokenBucket that allows ratePerSec sustained
+// tokens per second, with a burst of up to burst tokens.
+func NewTokenBucket(ratePerSec float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		ratePerSec: ratePerSec,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		lastRefill: time.Now(),
+	}
+}
+
+// Take blocks until a token is available, then consumes it.
+func (b *TokenBucket) Take() {
+	b.TakeOrDone(nil)
+}
+
+// TakeOrDone blocks until a token is available, then consumes it, unless
+// stop is closed first, in which case it returns early with no token taken.
+// Callers that hold a rate limiter across a reconnect/shutdown loop should
+// use this instead of Take so a slow, adaptively-throttled drain can't stall
+// that loop for a full refill wait.
+func (b *TokenBucket) TakeOrDone(stop <-chan struct{}) {
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+		wait := time.Duration(float64(time.Second) / b.ratePerSec)
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (b *TokenBucket) refill() {
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens += elapsed * b.ratePerSec
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.lastRefill = now
+}
+
+// SetRate adjusts the refill rate in place.
+func (b *TokenBucket) SetRate(ratePerSec float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ratePerSec = ratePerSec
+}
+
+// Rate returns the current refill rate.
+func (b *TokenBucket) Rate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ratePerSec
+}
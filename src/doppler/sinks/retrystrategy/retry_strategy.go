@@ -0,0 +1,26 @@
+package retrystrategy
+
+import (
+	"math"
+	"time"
+)
+
+// RetryStrategy computes how long to back off before the numberOfTries-th
+// reconnect attempt.
+type RetryStrategy func(numberOfTries int) time.Duration
+
+// NewExponentialRetryStrategy returns a RetryStrategy that doubles the
+// backoff on every retry, capped at one minute.
+func NewExponentialRetryStrategy() RetryStrategy {
+	return func(numberOfTries int) time.Duration {
+		if numberOfTries == 0 {
+			return 0
+		}
+
+		duration := time.Duration(math.Pow(2, float64(numberOfTries))) * 100 * time.Millisecond
+		if duration > time.Minute {
+			return time.Minute
+		}
+		return duration
+	}
+}
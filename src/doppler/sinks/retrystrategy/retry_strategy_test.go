@@ -0,0 +1,39 @@
+package retrystrategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialRetryStrategyReturnsZeroForFirstTry(t *testing.T) {
+	backoff := NewExponentialRetryStrategy()
+
+	if got := backoff(0); got != 0 {
+		t.Errorf("expected no backoff before the first try, got %v", got)
+	}
+}
+
+func TestExponentialRetryStrategyDoublesEachTry(t *testing.T) {
+	backoff := NewExponentialRetryStrategy()
+
+	if got := backoff(1); got != 200*time.Millisecond {
+		t.Errorf("expected 200ms on try 1, got %v", got)
+	}
+	if got := backoff(2); got != 400*time.Millisecond {
+		t.Errorf("expected 400ms on try 2, got %v", got)
+	}
+	if got := backoff(3); got != 800*time.Millisecond {
+		t.Errorf("expected 800ms on try 3, got %v", got)
+	}
+}
+
+func TestExponentialRetryStrategyCapsAtOneMinute(t *testing.T) {
+	backoff := NewExponentialRetryStrategy()
+
+	if got := backoff(10); got != time.Minute {
+		t.Errorf("expected backoff to cap at 1 minute, got %v", got)
+	}
+	if got := backoff(20); got != time.Minute {
+		t.Errorf("expected backoff to stay capped at 1 minute, got %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package sinks
+
+import (
+	"github.com/cloudfoundry/dropsonde/events"
+	"github.com/cloudfoundry/loggregatorlib/cfcomponent/instrumentation"
+)
+
+// Sink is anything that can receive a stream of envelopes for a single
+// application and report on its own health.
+type Sink interface {
+	Run(inputChan <-chan *events.Envelope)
+	Disconnect()
+	Identifier() string
+	StreamId() string
+	ShouldReceiveErrors() bool
+	GetInstrumentationMetric() instrumentation.Metric
+	UpdateDroppedMessageCount(messageCount int64)
+}
+
+// MultiMetricSink is satisfied by sinks that have more to report than the
+// single headline metric GetInstrumentationMetric carries, such as
+// SyslogSink's live rate-limiter and connection state. Widening Sink
+// itself to return a slice would break every existing Sink implementor
+// across doppler, so callers that want the full set should type-assert
+// for MultiMetricSink instead.
+type MultiMetricSink interface {
+	Sink
+	GetInstrumentationMetrics() []instrumentation.Metric
+}
+
+// DrainSink is a Sink backed by an operator-configured drain URL (syslog,
+// HTTPS, Kafka, ...). NewDrainSink is the single entry point for building
+// one from a drain URL, regardless of which backend the scheme selects.
+type DrainSink interface {
+	Sink
+}
@@ -0,0 +1,38 @@
+package syslog
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/cloudfoundry/dropsonde/events"
+)
+
+// eventTypesByQueryName maps the "events" drain URL query parameter's
+// comma-separated values to the envelope types they enable forwarding of.
+var eventTypesByQueryName = map[string]events.Envelope_EventType{
+	"log":     events.Envelope_LogMessage,
+	"metric":  events.Envelope_ContainerMetric,
+	"counter": events.Envelope_CounterEvent,
+}
+
+// parseAllowedEventTypes reads the "events" query parameter (e.g.
+// "?events=log,metric,counter") into the set of envelope types a sink
+// should forward. It defaults to log messages only, matching this sink's
+// behavior before container metrics and counter events could opt in.
+func parseAllowedEventTypes(query url.Values) map[events.Envelope_EventType]bool {
+	raw := query.Get("events")
+	if raw == "" {
+		return map[events.Envelope_EventType]bool{events.Envelope_LogMessage: true}
+	}
+
+	allowed := map[events.Envelope_EventType]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		if eventType, ok := eventTypesByQueryName[strings.TrimSpace(name)]; ok {
+			allowed[eventType] = true
+		}
+	}
+	if len(allowed) == 0 {
+		allowed[events.Envelope_LogMessage] = true
+	}
+	return allowed
+}
@@ -0,0 +1,37 @@
+package syslog
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/dropsonde/events"
+)
+
+// informationalPriority is the syslog PRI for facility "user-level
+// messages" (1) and severity "informational" (6): 1*8+6. ContainerMetric
+// and CounterEvent envelopes aren't OUT/ERR like a LogMessage, so they
+// always render at this fixed priority rather than the -1 sentinel
+// messagePriorityValue uses for an unrecognized LogMessage type.
+const informationalPriority = 14
+
+// renderEnvelope reduces an envelope to the (priority, source, sourceId,
+// message, timestamp) shape non-JSON writers expect. LogMessage envelopes
+// render as before; ContainerMetric and CounterEvent render as key=value
+// text so an opted-in drain can carry them over the same syslog
+// SD-ELEMENT/TAG plumbing as log lines.
+func renderEnvelope(envelope *events.Envelope) (priority int, source, sourceId string, message []byte, timestamp int64) {
+	switch envelope.GetEventType() {
+	case events.Envelope_ContainerMetric:
+		cm := envelope.GetContainerMetric()
+		return informationalPriority, "ContainerMetric", fmt.Sprintf("%d", cm.GetInstanceIndex()),
+			[]byte(fmt.Sprintf("cpuPercentage=%.2f memoryBytes=%d diskBytes=%d", cm.GetCpuPercentage(), cm.GetMemoryBytes(), cm.GetDiskBytes())),
+			envelope.GetTimestamp()
+	case events.Envelope_CounterEvent:
+		ce := envelope.GetCounterEvent()
+		return informationalPriority, "CounterEvent", ce.GetName(),
+			[]byte(fmt.Sprintf("delta=%d total=%d", ce.GetDelta(), ce.GetTotal())),
+			envelope.GetTimestamp()
+	default:
+		logMessage := envelope.GetLogMessage()
+		return messagePriorityValue(logMessage), logMessage.GetSourceType(), logMessage.GetSourceInstance(), logMessage.GetMessage(), logMessage.GetTimestamp()
+	}
+}
@@ -0,0 +1,109 @@
+package syslog
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/dropsonde/events"
+)
+
+func strPtr(s string) *string       { return &s }
+func int32Ptr(i int32) *int32       { return &i }
+func int64Ptr(i int64) *int64       { return &i }
+func float64Ptr(f float64) *float64 { return &f }
+func uint64Ptr(u uint64) *uint64    { return &u }
+
+func TestRenderEnvelopeRendersContainerMetricAsKeyValueText(t *testing.T) {
+	eventType := events.Envelope_ContainerMetric
+	envelope := &events.Envelope{
+		EventType: &eventType,
+		Timestamp: int64Ptr(1234),
+		ContainerMetric: &events.ContainerMetric{
+			InstanceIndex: int32Ptr(3),
+			CpuPercentage: float64Ptr(12.5),
+			MemoryBytes:   uint64Ptr(1024),
+			DiskBytes:     uint64Ptr(2048),
+		},
+	}
+
+	priority, source, sourceId, message, timestamp := renderEnvelope(envelope)
+
+	if priority != informationalPriority {
+		t.Errorf("expected priority %d, got %d", informationalPriority, priority)
+	}
+	if source != "ContainerMetric" {
+		t.Errorf("expected source %q, got %q", "ContainerMetric", source)
+	}
+	if sourceId != "3" {
+		t.Errorf("expected sourceId %q, got %q", "3", sourceId)
+	}
+	if string(message) != "cpuPercentage=12.50 memoryBytes=1024 diskBytes=2048" {
+		t.Errorf("unexpected message: %q", message)
+	}
+	if timestamp != 1234 {
+		t.Errorf("expected timestamp 1234, got %d", timestamp)
+	}
+}
+
+func TestRenderEnvelopeRendersCounterEventAsKeyValueText(t *testing.T) {
+	eventType := events.Envelope_CounterEvent
+	envelope := &events.Envelope{
+		EventType: &eventType,
+		Timestamp: int64Ptr(5678),
+		CounterEvent: &events.CounterEvent{
+			Name:  strPtr("requests"),
+			Delta: uint64Ptr(1),
+			Total: uint64Ptr(42),
+		},
+	}
+
+	priority, source, sourceId, message, timestamp := renderEnvelope(envelope)
+
+	if priority != informationalPriority {
+		t.Errorf("expected priority %d, got %d", informationalPriority, priority)
+	}
+	if source != "CounterEvent" {
+		t.Errorf("expected source %q, got %q", "CounterEvent", source)
+	}
+	if sourceId != "requests" {
+		t.Errorf("expected sourceId %q, got %q", "requests", sourceId)
+	}
+	if string(message) != "delta=1 total=42" {
+		t.Errorf("unexpected message: %q", message)
+	}
+	if timestamp != 5678 {
+		t.Errorf("expected timestamp 5678, got %d", timestamp)
+	}
+}
+
+func TestRenderEnvelopeFallsBackToLogMessageFields(t *testing.T) {
+	eventType := events.Envelope_LogMessage
+	messageType := events.LogMessage_OUT
+	envelope := &events.Envelope{
+		EventType: &eventType,
+		LogMessage: &events.LogMessage{
+			MessageType:    &messageType,
+			SourceType:     strPtr("APP"),
+			SourceInstance: strPtr("0"),
+			Message:        []byte("hello"),
+			Timestamp:      int64Ptr(999),
+		},
+	}
+
+	priority, source, sourceId, message, timestamp := renderEnvelope(envelope)
+
+	if priority != messagePriorityValue(envelope.GetLogMessage()) {
+		t.Errorf("expected priority %d, got %d", messagePriorityValue(envelope.GetLogMessage()), priority)
+	}
+	if source != "APP" {
+		t.Errorf("expected source %q, got %q", "APP", source)
+	}
+	if sourceId != "0" {
+		t.Errorf("expected sourceId %q, got %q", "0", sourceId)
+	}
+	if string(message) != "hello" {
+		t.Errorf("unexpected message: %q", message)
+	}
+	if timestamp != 999 {
+		t.Errorf("expected timestamp 999, got %d", timestamp)
+	}
+}
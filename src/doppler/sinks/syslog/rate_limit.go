@@ -0,0 +1,61 @@
+package syslog
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"doppler/sinks/ratelimiter"
+)
+
+// adaptiveRateFloorFraction bounds how far adaptive backoff will halve the
+// configured rate down to, so a persistently slow drain still gets a trickle
+// of messages through rather than stalling completely.
+const adaptiveRateFloorFraction = 0.125
+
+// newRateLimiter builds a TokenBucket from a drain URL's "rate"/"burst"/
+// "adaptive" query parameters, e.g. "?rate=1000/s&burst=200&adaptive=true".
+// It returns a nil limiter, with no error, when "rate" is absent.
+func newRateLimiter(query url.Values) (limiter *ratelimiter.TokenBucket, baseRate float64, adaptive bool, err error) {
+	rawRate := query.Get("rate")
+	if rawRate == "" {
+		return nil, 0, false, nil
+	}
+
+	rate, err := parseRate(rawRate)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	burst := int(rate)
+	if rawBurst := query.Get("burst"); rawBurst != "" {
+		burst, err = strconv.Atoi(rawBurst)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("invalid burst %q: %s", rawBurst, err)
+		}
+	}
+	if burst <= 0 {
+		return nil, 0, false, fmt.Errorf("invalid burst %d, must be greater than 0", burst)
+	}
+
+	return ratelimiter.NewTokenBucket(rate, burst), rate, query.Get("adaptive") == "true", nil
+}
+
+// parseRate parses a rate string of the form "N/s" into messages per second.
+func parseRate(raw string) (float64, error) {
+	parts := strings.SplitN(raw, "/", 2)
+	if len(parts) != 2 || parts[1] != "s" {
+		return 0, fmt.Errorf("invalid rate %q, expected format N/s", raw)
+	}
+
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %s", raw, err)
+	}
+	if rate <= 0 {
+		return 0, fmt.Errorf("invalid rate %q, must be greater than 0", raw)
+	}
+
+	return rate, nil
+}
@@ -1,10 +1,12 @@
 package syslog
 
 import (
-	"doppler/sinks"
+	"doppler/sinks/buffer"
+	"doppler/sinks/ratelimiter"
 	"doppler/sinks/retrystrategy"
 	"doppler/sinks/syslogwriter"
 	"fmt"
+	"net/url"
 	"sync"
 	"time"
 
@@ -33,11 +35,28 @@ type SyslogSink struct {
 	disconnectChannel   chan struct{}
 	dropsondeOrigin     string
 	disconnectOnce      sync.Once
+	rateLimiter         *ratelimiter.TokenBucket
+	baseRate            float64
+	minRate             float64
+	adaptive            bool
+	connected           int32
+	allowedEventTypes   map[events.Envelope_EventType]bool
 }
 
-func NewSyslogSink(appId string, drainUrl string, givenLogger *gosteno.Logger, syslogWriter syslogwriter.Writer, errorHandler func(string, string, string), dropsondeOrigin string) sinks.Sink {
+// NewSyslogSink returns a Sink writing to syslogWriter. It is registered
+// with sinks.NewDrainSink under the "syslog", "syslog-tls" and
+// "syslog-json" schemes, but
+// can also be constructed directly by callers that already have a Writer.
+//
+// drainUrl's query string may carry "rate=N/s" and "burst=N" to cap the
+// rate messages are sent at, and "adaptive=true" to have that rate halve
+// whenever the truncating buffer reports sustained drops and recover
+// gradually afterwards. It may also carry "events=log,metric,counter" to
+// forward ContainerMetric and CounterEvent envelopes alongside log
+// messages; absent, only log messages are forwarded.
+func NewSyslogSink(appId string, drainUrl string, givenLogger *gosteno.Logger, syslogWriter syslogwriter.Writer, errorHandler func(string, string, string), dropsondeOrigin string) *SyslogSink {
 	givenLogger.Debugf("Syslog Sink %s: Created for appId [%s]", drainUrl, appId)
-	return &SyslogSink{
+	sink := &SyslogSink{
 		appId:             appId,
 		drainUrl:          drainUrl,
 		Logger:            givenLogger,
@@ -45,7 +64,24 @@ func NewSyslogSink(appId string, drainUrl string, givenLogger *gosteno.Logger, s
 		handleSendError:   errorHandler,
 		disconnectChannel: make(chan struct{}),
 		dropsondeOrigin:   dropsondeOrigin,
+		allowedEventTypes: map[events.Envelope_EventType]bool{events.Envelope_LogMessage: true},
 	}
+
+	if parsedUrl, err := url.Parse(drainUrl); err == nil {
+		sink.allowedEventTypes = parseAllowedEventTypes(parsedUrl.Query())
+
+		rateLimiter, baseRate, adaptive, err := newRateLimiter(parsedUrl.Query())
+		if err != nil {
+			givenLogger.Warnf("Syslog Sink %s: ignoring rate limit: %s", drainUrl, err)
+		} else if rateLimiter != nil {
+			sink.rateLimiter = rateLimiter
+			sink.baseRate = baseRate
+			sink.minRate = baseRate * adaptiveRateFloorFraction
+			sink.adaptive = adaptive
+		}
+	}
+
+	return sink
 }
 
 func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
@@ -66,7 +102,7 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 					return
 				}
 
-				if v.GetEventType() != events.Envelope_LogMessage {
+				if !s.allowedEventTypes[v.GetEventType()] {
 					continue
 				}
 
@@ -77,7 +113,7 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 		}
 	}()
 
-	buffer := sinks.RunTruncatingBuffer(filteredChan, 100, s.Logger, s.dropsondeOrigin)
+	buf := buffer.RunTruncatingBuffer(filteredChan, 100, s.Logger, s.dropsondeOrigin)
 	timer := time.NewTimer(backoffStrategy(numberOfTries))
 	connected := false
 	defer timer.Stop()
@@ -104,6 +140,7 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 
 			s.Infof("Syslog Sink %s: successfully connected.", s.drainUrl)
 			connected = true
+			atomic.StoreInt32(&s.connected, 1)
 		}
 
 		s.Debugf("Syslog Sink %s: Waiting for activity\n", s.drainUrl)
@@ -111,24 +148,64 @@ func (s *SyslogSink) Run(inputChan <-chan *events.Envelope) {
 		select {
 		case <-s.disconnectChannel:
 			return
-		case messageEnvelope, ok := <-buffer.GetOutputChannel():
-			atomic.AddInt64(&s.droppedMessageCount, buffer.GetDroppedMessageCount())
+		case messageEnvelope, ok := <-buf.GetOutputChannel():
+			dropped := buf.GetDroppedMessageCount()
+			atomic.AddInt64(&s.droppedMessageCount, dropped)
+			s.adjustRate(dropped > 0)
 			if !ok {
 				s.Debugf("Syslog Sink %s: Closed listener channel detected. Closing.\n", s.drainUrl)
 				return
 			}
 			s.Debugf("Syslog Sink:Run: Received %s message from %s at %d. Sending data.", messageEnvelope.GetEventType().String(), messageEnvelope.GetOrigin(), messageEnvelope.Timestamp)
 
+			if s.rateLimiter != nil {
+				s.rateLimiter.TakeOrDone(s.disconnectChannel)
+				select {
+				case <-s.disconnectChannel:
+					return
+				default:
+				}
+			}
+
 			connected = s.sendMessage(messageEnvelope)
 			if connected {
 				numberOfTries = 0
 			} else {
 				numberOfTries++
+				atomic.StoreInt32(&s.connected, 0)
 			}
 		}
 	}
 }
 
+// adjustRate applies AIMD backpressure when adaptive rate limiting is
+// enabled: a round with drops halves the rate (down to minRate), a round
+// without drops nudges it back up towards baseRate.
+func (s *SyslogSink) adjustRate(dropped bool) {
+	if !s.adaptive || s.rateLimiter == nil {
+		return
+	}
+
+	current := s.rateLimiter.Rate()
+	if dropped {
+		next := current / 2
+		if next < s.minRate {
+			next = s.minRate
+		}
+		s.rateLimiter.SetRate(next)
+		return
+	}
+
+	if current >= s.baseRate {
+		return
+	}
+	next := current + s.baseRate*0.1
+	if next > s.baseRate {
+		next = s.baseRate
+	}
+	s.rateLimiter.SetRate(next)
+}
+
 func (s *SyslogSink) Disconnect() {
 	s.disconnectOnce.Do(func() { close(s.disconnectChannel) })
 }
@@ -146,9 +223,17 @@ func (s *SyslogSink) ShouldReceiveErrors() bool {
 }
 
 func (s *SyslogSink) sendMessage(messageEnvelope *events.Envelope) bool {
-	logMessage := messageEnvelope.GetLogMessage()
+	var err error
 
-	_, err := s.syslogWriter.Write(messagePriorityValue(logMessage), logMessage.GetMessage(), logMessage.GetSourceType(), logMessage.GetSourceInstance(), *logMessage.Timestamp)
+	// Writers that need more than a LogMessage's fields (e.g. JSONWriter,
+	// which preserves the full envelope) implement EnvelopeWriter; prefer
+	// that route when it's available.
+	if envelopeWriter, ok := s.syslogWriter.(syslogwriter.EnvelopeWriter); ok {
+		_, err = envelopeWriter.WriteEnvelope(messageEnvelope)
+	} else {
+		priority, source, sourceId, message, timestamp := renderEnvelope(messageEnvelope)
+		_, err = s.syslogWriter.Write(priority, message, source, sourceId, timestamp)
+	}
 
 	if err != nil {
 		s.Debugf("Syslog Sink %s: Error when trying to send data to sink. Backing off. Err: %v\n", s.drainUrl, err)
@@ -170,12 +255,32 @@ func messagePriorityValue(msg *events.LogMessage) int {
 	}
 }
 
+// GetInstrumentationMetric reports numberOfMessagesLost, the single
+// headline metric expected by callers that only know about Sink, matching
+// the convention HttpsSink and KafkaSink also follow. Callers that also
+// want the connection state and current rate should type-assert for
+// MultiMetricSink and call GetInstrumentationMetrics.
 func (s *SyslogSink) GetInstrumentationMetric() instrumentation.Metric {
 	count := atomic.LoadInt64(&s.droppedMessageCount)
-	if count != 0 {
-		return instrumentation.Metric{Name: "numberOfMessagesLost", Tags: map[string]interface{}{"appId": string(s.appId), "drainUrl": s.drainUrl}, Value: count}
+	tags := map[string]interface{}{"appId": string(s.appId), "drainUrl": s.drainUrl}
+	return instrumentation.Metric{Name: "numberOfMessagesLost", Tags: tags, Value: count}
+}
+
+func (s *SyslogSink) GetInstrumentationMetrics() []instrumentation.Metric {
+	tags := map[string]interface{}{"appId": string(s.appId), "drainUrl": s.drainUrl}
+	metrics := []instrumentation.Metric{s.GetInstrumentationMetric()}
+
+	connected := 0
+	if atomic.LoadInt32(&s.connected) == 1 {
+		connected = 1
 	}
-	return instrumentation.Metric{}
+	metrics = append(metrics, instrumentation.Metric{Name: "connected", Tags: tags, Value: connected})
+
+	if s.rateLimiter != nil {
+		metrics = append(metrics, instrumentation.Metric{Name: "currentRate", Tags: tags, Value: s.rateLimiter.Rate()})
+	}
+
+	return metrics
 }
 
 func (s *SyslogSink) UpdateDroppedMessageCount(messageCount int64) {
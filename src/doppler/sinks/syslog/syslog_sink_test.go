@@ -0,0 +1,80 @@
+package syslog
+
+import (
+	"testing"
+
+	"doppler/sinks/ratelimiter"
+)
+
+func newAdaptiveSink(baseRate float64) *SyslogSink {
+	return &SyslogSink{
+		rateLimiter: ratelimiter.NewTokenBucket(baseRate, 1),
+		baseRate:    baseRate,
+		minRate:     baseRate * adaptiveRateFloorFraction,
+		adaptive:    true,
+	}
+}
+
+func TestAdjustRateIgnoresNonAdaptiveSinks(t *testing.T) {
+	sink := newAdaptiveSink(100)
+	sink.adaptive = false
+
+	sink.adjustRate(true)
+
+	if rate := sink.rateLimiter.Rate(); rate != 100 {
+		t.Errorf("expected rate to stay at 100, got %v", rate)
+	}
+}
+
+func TestAdjustRateHalvesOnDrop(t *testing.T) {
+	sink := newAdaptiveSink(100)
+
+	sink.adjustRate(true)
+	if rate := sink.rateLimiter.Rate(); rate != 50 {
+		t.Errorf("expected rate to halve to 50, got %v", rate)
+	}
+
+	sink.adjustRate(true)
+	if rate := sink.rateLimiter.Rate(); rate != 25 {
+		t.Errorf("expected rate to halve to 25, got %v", rate)
+	}
+}
+
+func TestAdjustRateHalvingStopsAtFloor(t *testing.T) {
+	sink := newAdaptiveSink(100)
+
+	for i := 0; i < 10; i++ {
+		sink.adjustRate(true)
+	}
+
+	if rate := sink.rateLimiter.Rate(); rate != sink.minRate {
+		t.Errorf("expected rate to floor at %v, got %v", sink.minRate, rate)
+	}
+}
+
+func TestAdjustRateRecoversGraduallyWithoutDrops(t *testing.T) {
+	sink := newAdaptiveSink(100)
+	sink.rateLimiter.SetRate(50)
+
+	sink.adjustRate(false)
+
+	if rate := sink.rateLimiter.Rate(); rate != 60 {
+		t.Errorf("expected rate to recover to 60, got %v", rate)
+	}
+}
+
+func TestAdjustRateRecoveryStopsAtBaseRate(t *testing.T) {
+	sink := newAdaptiveSink(100)
+	sink.rateLimiter.SetRate(95)
+
+	sink.adjustRate(false)
+
+	if rate := sink.rateLimiter.Rate(); rate != 100 {
+		t.Errorf("expected rate to cap at base rate 100, got %v", rate)
+	}
+
+	sink.adjustRate(false)
+	if rate := sink.rateLimiter.Rate(); rate != 100 {
+		t.Errorf("expected rate to stay at base rate 100, got %v", rate)
+	}
+}
@@ -0,0 +1,128 @@
+package syslogwriter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/cloudfoundry/dropsonde/events"
+)
+
+// jsonEnvelope carries the full envelope over the wire, unlike the
+// LogMessage-only fields the RFC3164/RFC5424 formatters preserve. Fields
+// are shared across event types and left zero when they don't apply, e.g.
+// a ContainerMetric envelope leaves Message blank.
+type jsonEnvelope struct {
+	Origin         string  `json:"origin"`
+	Deployment     string  `json:"deployment,omitempty"`
+	Job            string  `json:"job,omitempty"`
+	Index          string  `json:"index,omitempty"`
+	Ip             string  `json:"ip,omitempty"`
+	Timestamp      int64   `json:"timestamp"`
+	EventType      string  `json:"event_type"`
+	AppId          string  `json:"app_id,omitempty"`
+	SourceType     string  `json:"source_type,omitempty"`
+	SourceInstance string  `json:"source_instance,omitempty"`
+	Message        string  `json:"message,omitempty"`
+	CpuPercentage  float64 `json:"cpu_percentage,omitempty"`
+	MemoryBytes    uint64  `json:"memory_bytes,omitempty"`
+	DiskBytes      uint64  `json:"disk_bytes,omitempty"`
+	Name           string  `json:"name,omitempty"`
+	Delta          uint64  `json:"delta,omitempty"`
+	Total          uint64  `json:"total,omitempty"`
+}
+
+// JSONWriter writes newline-delimited JSON envelopes to a drain over a
+// plain or TLS-wrapped TCP connection, selected by the "syslog-json"
+// scheme or "format=json" on a "syslog"/"syslog-tls" drain URL.
+type JSONWriter struct {
+	addr           string
+	useTLS         bool
+	skipCertVerify bool
+	conn           net.Conn
+}
+
+func newJSONWriter(addr string, useTLS, skipCertVerify bool) *JSONWriter {
+	return &JSONWriter{addr: addr, useTLS: useTLS, skipCertVerify: skipCertVerify}
+}
+
+func (w *JSONWriter) Connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+	}
+
+	conn, err := dialTCP(w.addr, w.useTLS, w.skipCertVerify)
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	return nil
+}
+
+// Write satisfies Writer for callers that don't have a full envelope; it
+// renders the fields it's given and leaves the rest blank. SyslogSink
+// prefers WriteEnvelope when possible.
+func (w *JSONWriter) Write(p int, b []byte, source, sourceId string, timestamp int64) (int, error) {
+	return w.writeJSON(jsonEnvelope{
+		Timestamp:      timestamp,
+		SourceType:     source,
+		SourceInstance: sourceId,
+		Message:        string(b),
+	})
+}
+
+func (w *JSONWriter) WriteEnvelope(envelope *events.Envelope) (int, error) {
+	doc := jsonEnvelope{
+		Origin:     envelope.GetOrigin(),
+		Deployment: envelope.GetDeployment(),
+		Job:        envelope.GetJob(),
+		Index:      envelope.GetIndex(),
+		Ip:         envelope.GetIp(),
+		Timestamp:  envelope.GetTimestamp(),
+		EventType:  envelope.GetEventType().String(),
+	}
+
+	switch envelope.GetEventType() {
+	case events.Envelope_ContainerMetric:
+		cm := envelope.GetContainerMetric()
+		doc.AppId = cm.GetApplicationId()
+		doc.CpuPercentage = cm.GetCpuPercentage()
+		doc.MemoryBytes = cm.GetMemoryBytes()
+		doc.DiskBytes = cm.GetDiskBytes()
+	case events.Envelope_CounterEvent:
+		ce := envelope.GetCounterEvent()
+		doc.Name = ce.GetName()
+		doc.Delta = ce.GetDelta()
+		doc.Total = ce.GetTotal()
+	default:
+		logMessage := envelope.GetLogMessage()
+		doc.AppId = logMessage.GetAppId()
+		doc.SourceType = logMessage.GetSourceType()
+		doc.SourceInstance = logMessage.GetSourceInstance()
+		doc.Message = string(logMessage.GetMessage())
+	}
+
+	return w.writeJSON(doc)
+}
+
+func (w *JSONWriter) writeJSON(doc jsonEnvelope) (int, error) {
+	if w.conn == nil {
+		return 0, fmt.Errorf("syslogwriter: Write called before successful Connect")
+	}
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return 0, err
+	}
+	body = append(body, '\n')
+
+	return w.conn.Write(body)
+}
+
+func (w *JSONWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
@@ -0,0 +1,122 @@
+package syslogwriter
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/cloudfoundry/dropsonde/events"
+)
+
+func strPtr(s string) *string       { return &s }
+func int64Ptr(i int64) *int64       { return &i }
+func float64Ptr(f float64) *float64 { return &f }
+func uint64Ptr(u uint64) *uint64    { return &u }
+
+func readJSONLine(t *testing.T, conn net.Conn) map[string]interface{} {
+	t.Helper()
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("expected to read a line, got error: %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(line, &doc); err != nil {
+		t.Fatalf("expected valid JSON, got error: %s (%q)", err, line)
+	}
+	return doc
+}
+
+func TestWriteEnvelopeEncodesContainerMetricFields(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	w := &JSONWriter{conn: client}
+
+	eventType := events.Envelope_ContainerMetric
+	envelope := &events.Envelope{
+		Origin:    strPtr("origin"),
+		Timestamp: int64Ptr(1234),
+		EventType: &eventType,
+		ContainerMetric: &events.ContainerMetric{
+			ApplicationId: strPtr("app-1"),
+			CpuPercentage: float64Ptr(12.5),
+			MemoryBytes:   uint64Ptr(1024),
+			DiskBytes:     uint64Ptr(2048),
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.WriteEnvelope(envelope)
+		done <- err
+	}()
+
+	doc := readJSONLine(t, server)
+	if err := <-done; err != nil {
+		t.Fatalf("expected WriteEnvelope to succeed, got %s", err)
+	}
+
+	if doc["app_id"] != "app-1" {
+		t.Errorf("expected app_id %q, got %v", "app-1", doc["app_id"])
+	}
+	if doc["cpu_percentage"] != 12.5 {
+		t.Errorf("expected cpu_percentage 12.5, got %v", doc["cpu_percentage"])
+	}
+	if doc["event_type"] != eventType.String() {
+		t.Errorf("expected event_type %q, got %v", eventType.String(), doc["event_type"])
+	}
+	if _, present := doc["message"]; present {
+		t.Errorf("expected no message field for a ContainerMetric envelope, got %v", doc["message"])
+	}
+}
+
+func TestWriteEnvelopeEncodesLogMessageFields(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	w := &JSONWriter{conn: client}
+
+	eventType := events.Envelope_LogMessage
+	envelope := &events.Envelope{
+		Origin:    strPtr("origin"),
+		Timestamp: int64Ptr(1234),
+		EventType: &eventType,
+		LogMessage: &events.LogMessage{
+			AppId:          strPtr("app-1"),
+			SourceType:     strPtr("APP"),
+			SourceInstance: strPtr("0"),
+			Message:        []byte("hello"),
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.WriteEnvelope(envelope)
+		done <- err
+	}()
+
+	doc := readJSONLine(t, server)
+	if err := <-done; err != nil {
+		t.Fatalf("expected WriteEnvelope to succeed, got %s", err)
+	}
+
+	if doc["message"] != "hello" {
+		t.Errorf("expected message %q, got %v", "hello", doc["message"])
+	}
+	if doc["source_type"] != "APP" {
+		t.Errorf("expected source_type %q, got %v", "APP", doc["source_type"])
+	}
+}
+
+func TestWriteEnvelopeFailsBeforeConnect(t *testing.T) {
+	w := newJSONWriter("example.com:1234", false, false)
+
+	if _, err := w.WriteEnvelope(&events.Envelope{}); err == nil {
+		t.Error("expected WriteEnvelope to fail before Connect establishes a connection")
+	}
+}
@@ -0,0 +1,80 @@
+package syslogwriter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Formats understood by NewWriter's "format" query parameter. JSON is
+// handled separately by NewWriter since it needs the full envelope rather
+// than a messageFormatter's LogMessage-shaped fields; it isn't a valid
+// argument to newMessageFormatter.
+const (
+	RFC3164 = "rfc3164"
+	RFC5424 = "rfc5424"
+	JSON    = "json"
+)
+
+// messageFormatter renders a single syslog message onto the wire. appId and
+// origin are fixed for the lifetime of a writer; source, sourceId and
+// timestamp vary per message.
+type messageFormatter interface {
+	format(p int, appId, origin, source, sourceId string, timestamp int64, msg []byte) []byte
+}
+
+func newMessageFormatter(name string) (messageFormatter, error) {
+	switch name {
+	case "", RFC3164:
+		return rfc3164Formatter{}, nil
+	case RFC5424:
+		return rfc5424Formatter{}, nil
+	default:
+		return nil, fmt.Errorf("syslogwriter: unsupported format %q", name)
+	}
+}
+
+// rfc3164Formatter emits the classic BSD syslog (RFC 3164) message that
+// drains have always received from this sink.
+type rfc3164Formatter struct{}
+
+func (rfc3164Formatter) format(p int, appId, origin, source, sourceId string, timestamp int64, msg []byte) []byte {
+	ts := time.Unix(0, timestamp)
+	return []byte(fmt.Sprintf("<%d>%s %s %s[%s/%s]: %s", p, ts.Format(time.Stamp), origin, appId, source, sourceId, msg))
+}
+
+// rfc5424Formatter emits RFC 5424 syslog, carrying Cloud Foundry envelope
+// metadata as a "cf" STRUCTURED-DATA element so downstream indexers can
+// pull app_id/instance_id/source_type without scraping the message body.
+type rfc5424Formatter struct{}
+
+func (rfc5424Formatter) format(p int, appId, origin, source, sourceId string, timestamp int64, msg []byte) []byte {
+	ts := time.Unix(0, timestamp)
+	structuredData := fmt.Sprintf(`[cf@47450 app_id="%s" instance_id="%s" source_type="%s"]`,
+		escapeSDParam(appId), escapeSDParam(sourceId), escapeSDParam(source))
+
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		p, ts.Format(time.RFC3339), nilIfEmpty(origin), nilIfEmpty(appId), nilIfEmpty(sourceId), nilIfEmpty(source), structuredData, msg))
+}
+
+// escapeSDParam escapes the three characters RFC 5424 forbids unescaped
+// inside a quoted SD-PARAM value: backslash, double quote, and closing
+// bracket. Without this, an app_id/instance_id/source_type containing one
+// of them would break the STRUCTURED-DATA element's syntax for anything
+// parsing the line.
+func escapeSDParam(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, `]`, `\]`, -1)
+	return s
+}
+
+// nilIfEmpty substitutes RFC 5424's NILVALUE ("-") for a HEADER field
+// that's empty, since the spec doesn't allow a blank HOSTNAME, APP-NAME,
+// PROCID, or MSGID.
+func nilIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package syslogwriter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMessageFormatterDefaultsToRFC3164(t *testing.T) {
+	formatter, err := newMessageFormatter("")
+	if err != nil {
+		t.Fatalf("expected no error for the default format, got %s", err)
+	}
+	if _, ok := formatter.(rfc3164Formatter); !ok {
+		t.Errorf("expected the default format to be rfc3164Formatter, got %T", formatter)
+	}
+}
+
+func TestNewMessageFormatterRejectsUnknownFormat(t *testing.T) {
+	if _, err := newMessageFormatter("bogus"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestRFC5424FormatterEscapesStructuredDataValues(t *testing.T) {
+	formatter := rfc5424Formatter{}
+
+	out := string(formatter.format(14, `app"1`, "origin", `src]1`, `inst\1`, 0, []byte("hello")))
+
+	if !strings.Contains(out, `app_id="app\"1"`) {
+		t.Errorf("expected the double quote in app_id to be escaped, got %s", out)
+	}
+	if !strings.Contains(out, `source_type="src\]1"`) {
+		t.Errorf("expected the closing bracket in source_type to be escaped, got %s", out)
+	}
+	if !strings.Contains(out, `instance_id="inst\\1"`) {
+		t.Errorf("expected the backslash in instance_id to be escaped, got %s", out)
+	}
+}
+
+func TestRFC5424FormatterUsesNilvalueForEmptyHeaderFields(t *testing.T) {
+	formatter := rfc5424Formatter{}
+
+	out := string(formatter.format(14, "", "", "", "", 0, []byte("hello")))
+
+	fields := strings.SplitN(out, " ", 8)
+	if len(fields) < 7 {
+		t.Fatalf("expected at least 7 space-separated fields, got %d: %q", len(fields), out)
+	}
+	for i, name := range []string{"HOSTNAME", "APP-NAME", "PROCID", "MSGID"} {
+		if fields[i+2] != "-" {
+			t.Errorf("expected %s to be NILVALUE \"-\", got %q", name, fields[i+2])
+		}
+	}
+}
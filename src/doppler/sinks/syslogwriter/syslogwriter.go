@@ -0,0 +1,33 @@
+package syslogwriter
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// NewWriter builds the Writer for a drain URL. The scheme selects the
+// transport: "syslog" dials plain TCP, "syslog-tls" dials TCP wrapped in
+// TLS (honoring skipCertVerify), and "syslog-json" dials plain TCP for
+// newline-delimited JSON envelopes. A "format" query parameter on a
+// "syslog"/"syslog-tls" URL selects the wire format instead (RFC3164, the
+// default, RFC5424, or "json"); e.g. "syslog-tls://host:6514?format=json".
+func NewWriter(appId, origin string, drainUrl *url.URL, skipCertVerify bool) (Writer, error) {
+	switch drainUrl.Scheme {
+	case "syslog-json":
+		return newJSONWriter(drainUrl.Host, false, skipCertVerify), nil
+	case "syslog", "syslog-tls":
+		useTLS := drainUrl.Scheme == "syslog-tls"
+		format := drainUrl.Query().Get("format")
+		if format == JSON {
+			return newJSONWriter(drainUrl.Host, useTLS, skipCertVerify), nil
+		}
+
+		formatter, err := newMessageFormatter(format)
+		if err != nil {
+			return nil, err
+		}
+		return newTcpWriter(appId, origin, drainUrl.Host, useTLS, skipCertVerify, formatter), nil
+	default:
+		return nil, fmt.Errorf("syslogwriter: unsupported drain scheme %q", drainUrl.Scheme)
+	}
+}
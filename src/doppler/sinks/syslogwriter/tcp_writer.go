@@ -0,0 +1,72 @@
+package syslogwriter
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+// tcpWriter writes syslog messages to a drain over a plain or TLS-wrapped
+// TCP connection, depending on whether the drain URL used the "syslog" or
+// "syslog-tls" scheme.
+type tcpWriter struct {
+	appId          string
+	origin         string
+	addr           string
+	useTLS         bool
+	skipCertVerify bool
+	formatter      messageFormatter
+	conn           net.Conn
+}
+
+func newTcpWriter(appId, origin, addr string, useTLS, skipCertVerify bool, formatter messageFormatter) *tcpWriter {
+	return &tcpWriter{
+		appId:          appId,
+		origin:         origin,
+		addr:           addr,
+		useTLS:         useTLS,
+		skipCertVerify: skipCertVerify,
+		formatter:      formatter,
+	}
+}
+
+func (w *tcpWriter) Connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+	}
+
+	conn, err := dialTCP(w.addr, w.useTLS, w.skipCertVerify)
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	return nil
+}
+
+// dialTCP opens a plain or TLS-wrapped TCP connection, shared by every
+// syslogwriter transport regardless of message format.
+func dialTCP(addr string, useTLS, skipCertVerify bool) (net.Conn, error) {
+	if useTLS {
+		return tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: skipCertVerify})
+	}
+	return net.Dial("tcp", addr)
+}
+
+func (w *tcpWriter) Write(p int, b []byte, source, sourceId string, timestamp int64) (int, error) {
+	if w.conn == nil {
+		return 0, fmt.Errorf("syslogwriter: Write called before successful Connect")
+	}
+
+	message := w.formatter.format(p, w.appId, w.origin, source, sourceId, timestamp, b)
+	message = append(message, '\n')
+
+	return w.conn.Write(message)
+}
+
+func (w *tcpWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
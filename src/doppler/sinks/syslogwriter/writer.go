@@ -0,0 +1,21 @@
+package syslogwriter
+
+import "github.com/cloudfoundry/dropsonde/events"
+
+// Writer delivers a single log line to a drain. Implementations own their
+// own connection lifecycle; Connect and Close may be called repeatedly as
+// the sink's retry loop reconnects after a dropped drain.
+type Writer interface {
+	Connect() error
+	Write(p int, b []byte, source, sourceId string, timestamp int64) (byteCount int, err error)
+	Close() error
+}
+
+// EnvelopeWriter is implemented by writers that preserve more of an
+// envelope than Write's LogMessage-shaped signature carries, such as
+// JSONWriter. SyslogSink prefers WriteEnvelope over Write when a writer
+// implements it.
+type EnvelopeWriter interface {
+	Writer
+	WriteEnvelope(envelope *events.Envelope) (byteCount int, err error)
+}